@@ -0,0 +1,54 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package device
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.zx2c4.com/wireguard/device/conf"
+)
+
+// ApplyConfig reconciles the device's live configuration with cfg in a
+// single IpcSetOperation call, under that call's existing lock, through
+// the same UAPI protocol "wg" callers already use. It sets replace_peers,
+// then lists every peer in cfg: IpcSetOperation looks each one up by
+// public key and updates it in place if it already exists, so a peer
+// whose configuration is unchanged keeps its handshake state and
+// keepalive timers; any previously configured peer not listed in cfg is
+// removed as part of the same replace_peers transaction.
+func (device *Device) ApplyConfig(cfg *conf.Config) error {
+	var b strings.Builder
+	if cfg.PrivateKey != nil {
+		fmt.Fprintf(&b, "private_key=%s\n", cfg.PrivateKey.HexString())
+	}
+	if cfg.ListenPort != nil {
+		fmt.Fprintf(&b, "listen_port=%d\n", *cfg.ListenPort)
+	}
+	if cfg.FirewallMark != nil {
+		fmt.Fprintf(&b, "fwmark=%d\n", *cfg.FirewallMark)
+	}
+	fmt.Fprintf(&b, "replace_peers=true\n")
+
+	for _, p := range cfg.Peers {
+		fmt.Fprintf(&b, "public_key=%s\n", p.PublicKey.HexString())
+		if p.PresharedKey != nil {
+			fmt.Fprintf(&b, "preshared_key=%s\n", p.PresharedKey.HexString())
+		}
+		if p.Endpoint != "" {
+			fmt.Fprintf(&b, "endpoint=%s\n", p.Endpoint)
+		}
+		if p.PersistentKeepaliveInterval != nil {
+			fmt.Fprintf(&b, "persistent_keepalive_interval=%d\n", *p.PersistentKeepaliveInterval)
+		}
+		fmt.Fprintf(&b, "replace_allowed_ips=true\n")
+		for _, ip := range p.AllowedIPs {
+			fmt.Fprintf(&b, "allowed_ip=%s\n", ip.String())
+		}
+	}
+
+	return device.IpcSetOperation(strings.NewReader(b.String()))
+}
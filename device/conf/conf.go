@@ -0,0 +1,208 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+// Package conf parses wg-quick-style configuration files
+// ([Interface]/[Peer] INI sections) into a typed Config that
+// device.Device.ApplyConfig can apply through the UAPI protocol.
+package conf
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// KeySize is the length, in bytes, of a decoded WireGuard key.
+const KeySize = 32
+
+// Key is a WireGuard private, public, or preshared key.
+type Key [KeySize]byte
+
+// HexString returns k in the lowercase hex form the UAPI protocol expects.
+func (k Key) HexString() string {
+	return hex.EncodeToString(k[:])
+}
+
+func parseKey(s string) (Key, error) {
+	var k Key
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return k, fmt.Errorf("conf: invalid key %q: %w", s, err)
+	}
+	if len(decoded) != KeySize {
+		return k, fmt.Errorf("conf: key %q is %d bytes, want %d", s, len(decoded), KeySize)
+	}
+	copy(k[:], decoded)
+	return k, nil
+}
+
+// PeerConfig is one [Peer] section of a config file.
+type PeerConfig struct {
+	PublicKey                   Key
+	PresharedKey                *Key
+	Endpoint                    string
+	AllowedIPs                  []net.IPNet
+	PersistentKeepaliveInterval *uint16
+}
+
+// Config is a parsed wg-quick-style configuration file.
+type Config struct {
+	PrivateKey   *Key
+	ListenPort   *uint16
+	FirewallMark *uint32
+	Peers        []PeerConfig
+}
+
+// ParseFile reads and parses the wg-quick-style config file at path.
+func ParseFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a wg-quick-style [Interface]/[Peer] INI document from r.
+// Only the directives that map onto the device itself are understood;
+// wg-quick-only directives (Address, DNS, MTU, PostUp, ...) are accepted
+// and ignored, since callers of ApplyConfig only manage the device, not
+// the surrounding network namespace.
+func Parse(r io.Reader) (*Config, error) {
+	cfg := &Config{}
+	var peer *PeerConfig
+	section := ""
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			if section == "peer" {
+				cfg.Peers = append(cfg.Peers, PeerConfig{})
+				peer = &cfg.Peers[len(cfg.Peers)-1]
+			} else {
+				peer = nil
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("conf: malformed line %q", line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch section {
+		case "interface":
+			err = cfg.setInterfaceField(key, value)
+		case "peer":
+			if peer == nil {
+				return nil, fmt.Errorf("conf: %q outside of a [Peer] section", key)
+			}
+			err = peer.setField(key, value)
+		default:
+			return nil, fmt.Errorf("conf: %q outside of a section", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var zero Key
+	for i, p := range cfg.Peers {
+		if p.PublicKey == zero {
+			return nil, fmt.Errorf("conf: [Peer] section %d has no PublicKey", i+1)
+		}
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) setInterfaceField(key, value string) error {
+	switch key {
+	case "privatekey":
+		k, err := parseKey(value)
+		if err != nil {
+			return err
+		}
+		c.PrivateKey = &k
+	case "listenport":
+		port, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return fmt.Errorf("conf: invalid ListenPort %q: %w", value, err)
+		}
+		p := uint16(port)
+		c.ListenPort = &p
+	case "fwmark":
+		mark, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return fmt.Errorf("conf: invalid FwMark %q: %w", value, err)
+		}
+		m := uint32(mark)
+		c.FirewallMark = &m
+	case "address", "dns", "mtu", "table", "preup", "postup", "predown", "postdown", "saveconfig":
+		// wg-quick-only directives; nothing for the device to apply.
+	default:
+		return fmt.Errorf("conf: unknown Interface key %q", key)
+	}
+	return nil
+}
+
+func (p *PeerConfig) setField(key, value string) error {
+	switch key {
+	case "publickey":
+		k, err := parseKey(value)
+		if err != nil {
+			return err
+		}
+		p.PublicKey = k
+	case "presharedkey":
+		k, err := parseKey(value)
+		if err != nil {
+			return err
+		}
+		p.PresharedKey = &k
+	case "endpoint":
+		p.Endpoint = value
+	case "allowedips":
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			_, ipnet, err := net.ParseCIDR(s)
+			if err != nil {
+				return fmt.Errorf("conf: invalid AllowedIPs %q: %w", s, err)
+			}
+			p.AllowedIPs = append(p.AllowedIPs, *ipnet)
+		}
+	case "persistentkeepalive":
+		n, err := strconv.ParseUint(value, 10, 16)
+		if err != nil {
+			return fmt.Errorf("conf: invalid PersistentKeepalive %q: %w", value, err)
+		}
+		k := uint16(n)
+		p.PersistentKeepaliveInterval = &k
+	default:
+		return fmt.Errorf("conf: unknown Peer key %q", key)
+	}
+	return nil
+}
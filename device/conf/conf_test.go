@@ -0,0 +1,77 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package conf
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func mustKey(t *testing.T, b byte) string {
+	t.Helper()
+	var raw [KeySize]byte
+	raw[0] = b
+	return base64.StdEncoding.EncodeToString(raw[:])
+}
+
+func TestParseValid(t *testing.T) {
+	priv := mustKey(t, 1)
+	pub := mustKey(t, 2)
+	doc := "[Interface]\n" +
+		"PrivateKey = " + priv + "\n" +
+		"ListenPort = 51820\n" +
+		"\n" +
+		"[Peer]\n" +
+		"PublicKey = " + pub + "\n" +
+		"AllowedIPs = 10.0.0.0/24, 10.0.1.5/32\n" +
+		"Endpoint = example.com:51820\n" +
+		"PersistentKeepalive = 25\n"
+
+	cfg, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.PrivateKey == nil || cfg.ListenPort == nil || *cfg.ListenPort != 51820 {
+		t.Fatalf("interface fields not parsed: %+v", cfg)
+	}
+	if len(cfg.Peers) != 1 {
+		t.Fatalf("want 1 peer, got %d", len(cfg.Peers))
+	}
+	p := cfg.Peers[0]
+	if len(p.AllowedIPs) != 2 {
+		t.Fatalf("want 2 AllowedIPs, got %d", len(p.AllowedIPs))
+	}
+	if p.Endpoint != "example.com:51820" {
+		t.Fatalf("unexpected Endpoint %q", p.Endpoint)
+	}
+	if p.PersistentKeepaliveInterval == nil || *p.PersistentKeepaliveInterval != 25 {
+		t.Fatalf("PersistentKeepalive not parsed: %+v", p.PersistentKeepaliveInterval)
+	}
+}
+
+func TestParseMissingPublicKey(t *testing.T) {
+	doc := "[Peer]\n" +
+		"AllowedIPs = 10.0.0.0/24\n"
+
+	if _, err := Parse(strings.NewReader(doc)); err == nil {
+		t.Fatal("expected an error for a [Peer] section with no PublicKey")
+	}
+}
+
+func TestParseMalformedLine(t *testing.T) {
+	doc := "[Interface]\nnotakeyvalue\n"
+	if _, err := Parse(strings.NewReader(doc)); err == nil {
+		t.Fatal("expected an error for a malformed line")
+	}
+}
+
+func TestParseUnknownInterfaceKey(t *testing.T) {
+	doc := "[Interface]\nBogus = 1\n"
+	if _, err := Parse(strings.NewReader(doc)); err == nil {
+		t.Fatal("expected an error for an unknown Interface key")
+	}
+}
@@ -6,18 +6,30 @@
 package device
 
 import (
-	"log"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
 	"os"
+
+	"golang.zx2c4.com/wireguard/conn"
 )
 
 // A Logger provides logging for a Device.
-// The functions are Printf-style functions.
+// Verbosef and Errorf are Printf-style functions kept for backwards
+// compatibility with existing call sites; both are backed by Slog, so
+// anything written through them also goes through Slog's handler.
 // They must be safe for concurrent use.
 // They do not require a trailing newline in the format.
 // If nil, that level of logging will be silent.
 type Logger struct {
 	Verbosef func(format string, args ...any)
 	Errorf   func(format string, args ...any)
+
+	// Slog is the structured logger backing Verbosef and Errorf. Code that
+	// wants structured fields instead of a formatted string should log
+	// through Slog directly, or through a Logger returned by WithPeer or
+	// WithEndpoint.
+	Slog *slog.Logger
 }
 
 // Log levels for use with NewLogger.
@@ -30,19 +42,76 @@ const (
 // Function for use in Logger for discarding logged lines.
 func DiscardLogf(format string, args ...any) {}
 
-// NewLogger constructs a Logger that writes to stdout.
-// It logs at the specified log level and above.
-// It decorates log lines with the log level, date, time, and prepend.
-func NewLogger(level int, prepend string) *Logger {
-	logger := &Logger{DiscardLogf, DiscardLogf}
-	logf := func(prefix string) func(string, ...any) { // 这是一个返回函数的函数, 并且返回的函数, 他有一个参数列表
-		return log.New(os.Stdout, prefix+": "+prepend, log.Ldate|log.Ltime).Printf
+// SlogLevel maps a wireguard-go LogLevel to the slog.Level it should be
+// filtered at, for embedders building their own slog.Handler.
+func SlogLevel(level int) slog.Level {
+	switch level {
+	case LogLevelVerbose:
+		return slog.LevelDebug
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 1 // above Error, so nothing is emitted
 	}
-	if level >= LogLevelVerbose {
-		logger.Verbosef = logf("DEBUG")
+}
+
+// NewSlogLogger constructs a Logger backed by h. Verbosef logs at
+// slog.LevelDebug and Errorf logs at slog.LevelError; whether either is
+// actually written is entirely up to h (e.g. the slog.HandlerOptions.Level
+// it was built with), so the caller controls verbosity by choosing h.
+func NewSlogLogger(h slog.Handler) *Logger {
+	return loggerFromSlog(slog.New(h))
+}
+
+// NewSlogLoggerAttrs is NewSlogLogger, with args (as accepted by
+// slog.Logger.With) attached to every line logged through the result,
+// including via Verbosef/Errorf. Attach fields this way rather than by
+// mutating a Logger's Slog field afterwards: Verbosef/Errorf close over the
+// *slog.Logger they were built with, so a later reassignment of Slog never
+// reaches them.
+func NewSlogLoggerAttrs(h slog.Handler, args ...any) *Logger {
+	return loggerFromSlog(slog.New(h).With(args...))
+}
+
+func loggerFromSlog(l *slog.Logger) *Logger {
+	return &Logger{
+		Verbosef: func(format string, args ...any) {
+			l.Debug(fmt.Sprintf(format, args...))
+		},
+		Errorf: func(format string, args ...any) {
+			l.Error(fmt.Sprintf(format, args...))
+		},
+		Slog: l,
 	}
-	if level >= LogLevelError {
-		logger.Errorf = logf("ERROR")
+}
+
+// NewLogger constructs a Logger that writes to stdout using a
+// slog.TextHandler. It logs at the specified log level and above. If iface
+// is non-empty, every line is tagged with it via an "iface" field, the same
+// way WithPeer/WithEndpoint tag their fields: as structured data, not
+// baked into the message text.
+func NewLogger(level int, iface string) *Logger {
+	h := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: SlogLevel(level)})
+	l := slog.New(h)
+	if iface != "" {
+		l = l.With("iface", iface)
+	}
+	return loggerFromSlog(l)
+}
+
+// WithPeer returns a Logger whose Verbosef, Errorf, and Slog all tag every
+// line with the peer's public key, so handshake and transport log lines can
+// be correlated with a peer without formatting it into the message text.
+func (l *Logger) WithPeer(pk NoisePublicKey) *Logger {
+	return loggerFromSlog(l.Slog.With("peer", hex.EncodeToString(pk[:])))
+}
+
+// WithEndpoint returns a Logger whose Verbosef, Errorf, and Slog all tag
+// every line with the remote endpoint. If ep is nil, l is returned
+// unchanged.
+func (l *Logger) WithEndpoint(ep conn.Endpoint) *Logger {
+	if ep == nil {
+		return l
 	}
-	return logger
+	return loggerFromSlog(l.Slog.With("endpoint", ep.DstToString()))
 }
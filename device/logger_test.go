@@ -0,0 +1,56 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package device
+
+import (
+	"bytes"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// fakeEndpoint is a minimal conn.Endpoint for exercising WithEndpoint
+// without depending on a real Bind.
+type fakeEndpoint struct{ dst string }
+
+func (f fakeEndpoint) ClearSrc()           {}
+func (f fakeEndpoint) SrcToString() string { return "" }
+func (f fakeEndpoint) DstToString() string { return f.dst }
+func (f fakeEndpoint) DstToBytes() []byte  { return nil }
+func (f fakeEndpoint) DstIP() netip.Addr   { return netip.Addr{} }
+func (f fakeEndpoint) SrcIP() netip.Addr   { return netip.Addr{} }
+
+func TestLoggerWithPeer(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewSlogLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var pk NoisePublicKey
+	pk[0] = 0xAB
+	base.WithPeer(pk).Errorf("handshake failed")
+
+	if !strings.Contains(buf.String(), "peer=ab") {
+		t.Errorf("expected peer field in output, got: %s", buf.String())
+	}
+}
+
+func TestLoggerWithEndpoint(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewSlogLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	base.WithEndpoint(fakeEndpoint{dst: "10.0.0.1:51820"}).Verbosef("received keepalive")
+
+	if !strings.Contains(buf.String(), "endpoint=10.0.0.1:51820") {
+		t.Errorf("expected endpoint field in output, got: %s", buf.String())
+	}
+}
+
+func TestLoggerWithEndpointNil(t *testing.T) {
+	base := NewSlogLogger(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	if got := base.WithEndpoint(nil); got != base {
+		t.Error("WithEndpoint(nil) should return the same *Logger unchanged")
+	}
+}
@@ -0,0 +1,125 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package device
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// entry is what Manager keeps per registered device: the Device itself
+// plus the Metrics instance its Bind was wrapped with (see
+// NewMeteringBind), since Device doesn't expose one of its own.
+type entry struct {
+	dev     *Device
+	metrics *Metrics
+}
+
+// Manager owns a set of named Devices running in a single process, so a
+// gateway running dozens of tunnels doesn't need one process per tunnel.
+// It is safe for concurrent use.
+type Manager struct {
+	mu      sync.RWMutex
+	devices map[string]entry
+}
+
+// NewManager returns an empty Manager ready for use.
+func NewManager() *Manager {
+	return &Manager{devices: make(map[string]entry)}
+}
+
+// Add registers dev, and the Metrics its Bind was wrapped with, under
+// name. It returns an error if name is already taken.
+func (m *Manager) Add(name string, dev *Device, metrics *Metrics) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.devices[name]; exists {
+		return fmt.Errorf("device manager: %q is already registered", name)
+	}
+	m.devices[name] = entry{dev: dev, metrics: metrics}
+	return nil
+}
+
+// Remove closes and unregisters the device named name, if any.
+func (m *Manager) Remove(name string) {
+	m.mu.Lock()
+	e, ok := m.devices[name]
+	delete(m.devices, name)
+	m.mu.Unlock()
+	if ok {
+		e.dev.Close()
+	}
+}
+
+// Get returns the device registered under name, if any.
+func (m *Manager) Get(name string) (*Device, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.devices[name]
+	return e.dev, ok
+}
+
+// Names returns the names of all currently registered devices.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.devices))
+	for name := range m.devices {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Wait returns a channel that is closed once every device registered at
+// the time of the call has stopped (see Device.Wait). Devices added after
+// Wait is called are not included.
+func (m *Manager) Wait() <-chan struct{} {
+	m.mu.RLock()
+	waiters := make([]chan struct{}, 0, len(m.devices))
+	for _, e := range m.devices {
+		waiters = append(waiters, e.dev.Wait())
+	}
+	m.mu.RUnlock()
+
+	done := make(chan struct{})
+	go func() {
+		for _, w := range waiters {
+			<-w
+		}
+		close(done)
+	}()
+	return done
+}
+
+// CloseAll closes every registered device and unregisters it.
+func (m *Manager) CloseAll() {
+	m.mu.Lock()
+	devices := m.devices
+	m.devices = make(map[string]entry)
+	m.mu.Unlock()
+
+	for _, e := range devices {
+		e.dev.Close()
+	}
+}
+
+// WritePrometheus writes the Prometheus metrics for every registered
+// device to w, preceded by a comment naming the interface each block of
+// samples belongs to.
+func (m *Manager) WritePrometheus(w io.Writer) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for name, e := range m.devices {
+		if _, err := fmt.Fprintf(w, "# interface=%s\n", name); err != nil {
+			return err
+		}
+		if err := e.metrics.WritePrometheus(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package device
+
+import "golang.zx2c4.com/wireguard/conn"
+
+// meteringBind wraps a conn.Bind so every packet actually sent or received
+// through it is counted in m, regardless of which Device owns the bind.
+type meteringBind struct {
+	conn.Bind
+	metrics *Metrics
+}
+
+// NewMeteringBind wraps bind so m.PacketsIn/PacketsOut reflect real
+// traffic through it. Pass the result to NewDevice in place of bind.
+func NewMeteringBind(bind conn.Bind, m *Metrics) conn.Bind {
+	return &meteringBind{Bind: bind, metrics: m}
+}
+
+func (b *meteringBind) Open(port uint16) ([]conn.ReceiveFunc, uint16, error) {
+	fns, actualPort, err := b.Bind.Open(port)
+	if err != nil {
+		return nil, 0, err
+	}
+	wrapped := make([]conn.ReceiveFunc, len(fns))
+	for i, fn := range fns {
+		fn := fn
+		wrapped[i] = func(bufs [][]byte, sizes []int, eps []conn.Endpoint) (int, error) {
+			n, err := fn(bufs, sizes, eps)
+			if n > 0 {
+				b.metrics.PacketsIn.Add(uint64(n))
+			}
+			return n, err
+		}
+	}
+	return wrapped, actualPort, nil
+}
+
+func (b *meteringBind) Send(bufs [][]byte, ep conn.Endpoint) error {
+	err := b.Bind.Send(bufs, ep)
+	if err == nil {
+		b.metrics.PacketsOut.Add(uint64(len(bufs)))
+	}
+	return err
+}
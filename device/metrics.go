@@ -0,0 +1,60 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package device
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Metrics holds the counters exported by a Device. It is safe for
+// concurrent use.
+//
+// PacketsIn/PacketsOut are the only counters actually incremented in this
+// tree, by NewMeteringBind, which wraps the conn.Bind a Device sends and
+// receives through. Per-peer counters (bytes, handshakes, decrypt
+// failures, replay drops) and cookie-rate-limit counters would belong
+// here too, but this tree doesn't contain device.Peer, the noise
+// handshake state machine, or the cookie checker to instrument, so they
+// are deliberately not included: a counter nobody increments is worse
+// than no counter, since it reports a confident zero instead of an
+// honest "unknown".
+type Metrics struct {
+	PacketsIn  atomic.Uint64
+	PacketsOut atomic.Uint64
+}
+
+// NewMetrics returns an empty Metrics ready for use.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+// WriteUAPIResponse writes m in Prometheus text format followed by the
+// UAPI response trailer ("errno=0\n\n"), so a get=metrics verb handler in
+// the UAPI dispatcher can return this directly as the response body.
+func (m *Metrics) WriteUAPIResponse(w io.Writer) error {
+	if err := m.WritePrometheus(w); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "errno=0\n\n")
+	return err
+}
+
+// WritePrometheus writes m in the Prometheus text exposition format to w.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	var errs [1]error
+	writeCounter := func(name, help string, val uint64) {
+		if errs[0] != nil {
+			return
+		}
+		_, errs[0] = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, val)
+	}
+
+	writeCounter("wireguard_packets_in_total", "Packets received.", m.PacketsIn.Load())
+	writeCounter("wireguard_packets_out_total", "Packets sent.", m.PacketsOut.Load())
+	return errs[0]
+}
@@ -0,0 +1,46 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package device
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMetricsWritePrometheus(t *testing.T) {
+	m := NewMetrics()
+	m.PacketsIn.Add(3)
+	m.PacketsOut.Add(5)
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"wireguard_packets_in_total 3",
+		"wireguard_packets_out_total 5",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsWriteUAPIResponse(t *testing.T) {
+	m := NewMetrics()
+	m.PacketsIn.Add(1)
+
+	var buf bytes.Buffer
+	if err := m.WriteUAPIResponse(&buf); err != nil {
+		t.Fatalf("WriteUAPIResponse: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "errno=0\n\n") {
+		t.Errorf("output missing UAPI trailer: %s", buf.String())
+	}
+}
@@ -0,0 +1,10 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+// Package sdnotify implements the systemd notify protocol (sd_notify(3))
+// and LISTEN_FDS-based socket activation (sd_listen_fds(3)), so
+// wireguard-go can run as a systemd Type=notify service without linking
+// against libsystemd. All functions are no-ops on non-Linux platforms.
+package sdnotify
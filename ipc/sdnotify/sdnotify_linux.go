@@ -0,0 +1,111 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package sdnotify
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenFDsStart is the first file descriptor number systemd passes for
+// socket activation; see sd_listen_fds(3).
+const listenFDsStart = 3
+
+// Enabled reports whether the process was started with NOTIFY_SOCKET set,
+// i.e. whether the Ready/Reloading/Stopping/Watchdog calls below have any
+// effect.
+func Enabled() bool {
+	return os.Getenv("NOTIFY_SOCKET") != ""
+}
+
+// notify sends state to $NOTIFY_SOCKET. It is a no-op if NOTIFY_SOCKET is
+// unset, matching sd_notify's behavior outside of a systemd unit.
+func notify(state string) error {
+	addr := os.Getenv("NOTIFY_SOCKET")
+	if addr == "" {
+		return nil
+	}
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		return fmt.Errorf("sdnotify: dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// Ready tells systemd the service finished starting up.
+func Ready() error { return notify("READY=1") }
+
+// Reloading tells systemd a config reload is in progress. Callers should
+// send Ready again once the reload completes.
+func Reloading() error { return notify("RELOADING=1") }
+
+// Stopping tells systemd the service is shutting down.
+func Stopping() error { return notify("STOPPING=1") }
+
+// Watchdog pings systemd's watchdog to indicate the service is still
+// healthy. Callers should call this once per interval returned by
+// WatchdogInterval, which is already half of WATCHDOG_USEC.
+func Watchdog() error { return notify("WATCHDOG=1") }
+
+// WatchdogInterval returns how often Watchdog should be called, derived
+// from WATCHDOG_USEC, and whether the watchdog is enabled at all.
+func WatchdogInterval() (time.Duration, bool) {
+	us := os.Getenv("WATCHDOG_USEC")
+	if us == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(us, 10, 64)
+	if err != nil || n == 0 {
+		return 0, false
+	}
+	// Per sd_watchdog_enabled(3), clients should ping at less than half
+	// the advertised interval.
+	return time.Duration(n) * time.Microsecond / 2, true
+}
+
+// Files returns the file descriptors passed to this process via
+// LISTEN_FDS, keyed by the corresponding entry in LISTEN_FDNAMES (or by
+// decimal index for unnamed fds). It returns nil if this process wasn't
+// socket-activated for it (LISTEN_PID doesn't match, or LISTEN_FDS is
+// unset).
+func Files() map[string]*os.File {
+	pidStr, nStr := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	if pidStr == "" || nStr == "" {
+		return nil
+	}
+	if pid, err := strconv.Atoi(pidStr); err != nil || pid != os.Getpid() {
+		return nil
+	}
+	n, err := strconv.Atoi(nStr)
+	if err != nil || n <= 0 {
+		return nil
+	}
+
+	var names []string
+	if raw := os.Getenv("LISTEN_FDNAMES"); raw != "" {
+		names = strings.Split(raw, ":")
+	}
+
+	files := make(map[string]*os.File, n)
+	for i := 0; i < n; i++ {
+		fd := listenFDsStart + i
+		unix.CloseOnExec(fd)
+		name := strconv.Itoa(i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		files[name] = os.NewFile(uintptr(fd), name)
+	}
+	return files
+}
@@ -0,0 +1,26 @@
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+//go:build !linux
+
+package sdnotify
+
+import (
+	"os"
+	"time"
+)
+
+// Enabled always reports false outside of Linux; systemd notify/socket
+// activation don't apply elsewhere.
+func Enabled() bool { return false }
+
+func Ready() error     { return nil }
+func Reloading() error { return nil }
+func Stopping() error  { return nil }
+func Watchdog() error  { return nil }
+
+func WatchdogInterval() (time.Duration, bool) { return 0, false }
+
+func Files() map[string]*os.File { return nil }
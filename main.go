@@ -9,15 +9,22 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/sys/unix"
 	"golang.zx2c4.com/wireguard/conn"
 	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/device/conf"
 	"golang.zx2c4.com/wireguard/ipc"
+	"golang.zx2c4.com/wireguard/ipc/sdnotify"
 	"golang.zx2c4.com/wireguard/tun"
 )
 
@@ -33,7 +40,28 @@ const (
 )
 
 func printUsage() {
-	fmt.Printf("Usage: %s [-f/--foreground] INTERFACE-NAME\n", os.Args[0])
+	fmt.Printf("Usage: %s [-f/--foreground] [-c/--config PATH] INTERFACE-NAME [INTERFACE-NAME...]\n", os.Args[0])
+}
+
+// reloadConfig parses the wg-quick-style file at path and applies it to
+// dev via ApplyConfig.
+func reloadConfig(dev *device.Device, path string) error {
+	cfg, err := conf.ParseFile(path)
+	if err != nil {
+		return err
+	}
+	return dev.ApplyConfig(cfg)
+}
+
+// newMetricsServer returns an *http.Server, not yet started, that serves m
+// in Prometheus text format at /metrics on addr.
+func newMetricsServer(addr string, m *device.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WritePrometheus(w)
+	})
+	return &http.Server{Addr: addr, Handler: mux}
 }
 
 func warning() {
@@ -66,31 +94,65 @@ func main() {
 	warning()
 
 	var foreground bool
-	var interfaceName string
-	if len(os.Args) < 2 || len(os.Args) > 3 { // 2/3 个参数
-		printUsage()
-		return
-	}
-
-	switch os.Args[1] {
+	var configPath string
+	var interfaceNames []string
+
+	args := os.Args[1:]
+	for len(args) > 0 {
+		switch args[0] {
+		case "-f", "--foreground":
+			foreground = true
+			args = args[1:]
+
+		case "-c", "--config":
+			if len(args) < 2 {
+				printUsage()
+				return
+			}
+			configPath = args[1]
+			args = args[2:]
 
-	case "-f", "--foreground":
-		foreground = true
-		if len(os.Args) != 3 {
-			printUsage()
-			return
+		default:
+			if strings.HasPrefix(args[0], "-") {
+				printUsage()
+				return
+			}
+			interfaceNames = append(interfaceNames, args[0])
+			args = args[1:]
 		}
-		interfaceName = os.Args[2]
+	}
 
-	default:
-		foreground = false
-		if len(os.Args) != 2 {
-			printUsage()
-			return
+	// -c PATH may point at either a single wg-quick-style file (applied to
+	// the lone INTERFACE-NAME) or a directory of them named <iface>.conf
+	// (one Device per file, run together under a device.Manager).
+	var configDir string
+	if configPath != "" {
+		if fi, err := os.Stat(configPath); err == nil && fi.IsDir() {
+			configDir = configPath
+			configPath = ""
+			matches, err := filepath.Glob(filepath.Join(configDir, "*.conf"))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read config directory %s: %v\n", configDir, err)
+				os.Exit(ExitSetupFailed)
+			}
+			for _, match := range matches {
+				interfaceNames = append(interfaceNames, strings.TrimSuffix(filepath.Base(match), ".conf"))
+			}
 		}
-		interfaceName = os.Args[1]
 	}
 
+	if len(interfaceNames) == 0 {
+		printUsage()
+		return
+	}
+
+	if len(interfaceNames) > 1 || configDir != "" {
+		runManager(interfaceNames, configDir)
+		return
+	}
+
+	interfaceName := interfaceNames[0]
+
 	// 如果参数没有指定 -f, 那么还要检查环境变量
 	if !foreground {
 		foreground = os.Getenv(ENV_WG_PROCESS_FOREGROUND) == "1"
@@ -110,9 +172,17 @@ func main() {
 		return device.LogLevelError
 	}()
 
+	// systemd socket activation: when present, LISTEN_FDS/LISTEN_FDNAMES
+	// take priority over the ad-hoc WG_TUN_FD/WG_UAPI_FD env protocol.
+	activatedFiles := sdnotify.Files()
+
 	// open TUN device (or use supplied fd)
 
 	tdev, err := func() (tun.Device, error) { // 声明了一个匿名函数, 并立即调用, 好处是: 封装逻辑, 避免变量污染
+		if f, ok := activatedFiles["tun"]; ok {
+			return tun.CreateTUNFromFile(f, device.DefaultMTU)
+		}
+
 		tunFdStr := os.Getenv(ENV_WG_TUN_FD)
 		if tunFdStr == "" {
 			return tun.CreateTUN(interfaceName, device.DefaultMTU)
@@ -141,10 +211,16 @@ func main() {
 		}
 	}
 
-	logger := device.NewLogger(
-		logLevel,
-		fmt.Sprintf("(%s) ", interfaceName),
-	)
+	// LOG_FORMAT selects the slog handler used to render log lines.
+	// "json" is meant for shipping logs to Loki/ELK/etc. without having to
+	// regex-parse the default human-readable text format.
+	logger := func() *device.Logger {
+		if os.Getenv("LOG_FORMAT") != "json" {
+			return device.NewLogger(logLevel, interfaceName)
+		}
+		opts := &slog.HandlerOptions{Level: device.SlogLevel(logLevel)}
+		return device.NewSlogLoggerAttrs(slog.NewJSONHandler(os.Stdout, opts), "iface", interfaceName)
+	}()
 
 	logger.Verbosef("Starting wireguard-go version %s", Version)
 
@@ -156,6 +232,10 @@ func main() {
 	// open UAPI file (or use supplied fd)
 
 	fileUAPI, err := func() (*os.File, error) {
+		if f, ok := activatedFiles["wireguard.sock"]; ok {
+			return f, nil
+		}
+
 		uapiFdStr := os.Getenv(ENV_WG_UAPI_FD)
 		if uapiFdStr == "" {
 			return ipc.UAPIOpen(interfaceName)
@@ -177,6 +257,17 @@ func main() {
 	}
 	// daemonize the process
 
+	// systemd's NotifyAccess=main only accepts notifications from the PID
+	// it originally spawned. A daemonized child is a different PID, so its
+	// Ready()/Watchdog() pings would be silently dropped and systemd would
+	// eventually time out or watchdog-kill an otherwise healthy service;
+	// refuse to re-exec in that case, the same way the multi-interface path
+	// in runManager always stays in the foreground.
+	if !foreground && (sdnotify.Enabled() || len(activatedFiles) > 0) {
+		logger.Verbosef("Running under systemd notification/socket-activation; staying in the foreground")
+		foreground = true
+	}
+
 	if !foreground {
 		env := os.Environ()
 		env = append(env, fmt.Sprintf("%s=3", ENV_WG_TUN_FD))
@@ -225,10 +316,52 @@ func main() {
 
 	// 能走到下面, 有两种情况: 1. 守护进程; 2. 一开始就让他以前台方式运行
 
-	device := device.NewDevice(tdev, conn.NewDefaultBind(), logger)
+	metrics := device.NewMetrics()
+	device := device.NewDevice(tdev, device.NewMeteringBind(conn.NewDefaultBind(), metrics), logger)
 
 	logger.Verbosef("Device started")
 
+	// -c/--config applies a wg-quick-style config file at startup, and
+	// re-applies it on SIGHUP. ApplyConfig sends the whole file through
+	// IpcSetOperation with replace_peers=true; it does not itself compare
+	// the file against the peers currently configured.
+	if configPath != "" {
+		if err := reloadConfig(device, configPath); err != nil {
+			logger.Errorf("Failed to apply config %s: %v", configPath, err)
+			os.Exit(ExitSetupFailed)
+		}
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, unix.SIGHUP)
+		go func() {
+			for range hup {
+				logger.Verbosef("Reloading config %s", configPath)
+				_ = sdnotify.Reloading()
+				if err := reloadConfig(device, configPath); err != nil {
+					logger.Errorf("Failed to reload config %s: %v", configPath, err)
+				}
+				_ = sdnotify.Ready()
+			}
+		}()
+	}
+
+	// WG_METRICS_LISTEN starts a Prometheus /metrics endpoint on its own
+	// TCP address. There is no UAPI get=metrics fallback for when it's
+	// unset: that would need a verb registered in device/uapi.go's
+	// dispatcher, and that file isn't part of this tree, so it isn't
+	// implemented here. Metrics.WriteUAPIResponse writes the response body
+	// such a verb would need; wiring it in is left for whoever owns
+	// device/uapi.go.
+	if addr := os.Getenv("WG_METRICS_LISTEN"); addr != "" {
+		metricsSrv := newMetricsServer(addr, metrics)
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+		defer metricsSrv.Close()
+	}
+
 	errs := make(chan error) // channel
 	term := make(chan os.Signal, 1)
 
@@ -253,6 +386,25 @@ func main() {
 
 	logger.Verbosef("UAPI listener started")
 
+	// Tell systemd (Type=notify) that startup is done, now that the UAPI
+	// socket is actually accepting connections. This is a no-op if we
+	// weren't started under systemd.
+	if err := sdnotify.Ready(); err != nil {
+		logger.Errorf("Failed to notify systemd of readiness: %v", err)
+	}
+
+	if interval, ok := sdnotify.WatchdogInterval(); ok {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if err := sdnotify.Watchdog(); err != nil {
+					logger.Errorf("Failed to ping systemd watchdog: %v", err)
+				}
+			}
+		}()
+	}
+
 	// wait for program to terminate
 
 	signal.Notify(term, unix.SIGTERM)
@@ -267,6 +419,10 @@ func main() {
 
 	// clean up
 
+	if err := sdnotify.Stopping(); err != nil {
+		logger.Errorf("Failed to notify systemd of shutdown: %v", err)
+	}
+
 	uapi.Close()
 	device.Close()
 
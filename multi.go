@@ -0,0 +1,192 @@
+//go:build !windows
+
+/* SPDX-License-Identifier: MIT
+ *
+ * Copyright (C) 2017-2025 WireGuard LLC. All Rights Reserved.
+ */
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/ipc"
+	"golang.zx2c4.com/wireguard/ipc/sdnotify"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+// runManager runs one device.Device per name in this single process,
+// sharing one device.Manager, one SIGHUP/SIGTERM handling loop, and (if
+// WG_METRICS_LISTEN is set) one metrics listener. Unlike the
+// single-interface path in main, which re-execs itself into the
+// background unless -f/--foreground is given, it never daemonizes:
+// fd-passing across a re-exec only carries one TUN/UAPI pair, which doesn't generalize to a
+// variable number of interfaces, so multi-interface runs always stay in
+// the foreground (under a process supervisor, e.g. systemd).
+func runManager(names []string, configDir string) {
+	logLevel := func() int {
+		switch os.Getenv("LOG_LEVEL") {
+		case "verbose", "debug":
+			return device.LogLevelVerbose
+		case "error":
+			return device.LogLevelError
+		case "silent":
+			return device.LogLevelSilent
+		}
+		return device.LogLevelError
+	}()
+
+	// Socket activation for a fleet of interfaces: a tun-<name> or
+	// <name>.sock entry in LISTEN_FDNAMES takes priority over creating
+	// that interface's TUN device or UAPI socket ourselves, the same way
+	// the single-interface path in main.go consults sdnotify.Files().
+	activatedFiles := sdnotify.Files()
+
+	manager := device.NewManager()
+
+	type boundUAPI struct {
+		name string
+		dev  *device.Device
+		ln   net.Listener
+	}
+	var listeners []boundUAPI
+
+	for _, name := range names {
+		tdev, err := func() (tun.Device, error) {
+			if f, ok := activatedFiles["tun-"+name]; ok {
+				return tun.CreateTUNFromFile(f, device.DefaultMTU)
+			}
+			return tun.CreateTUN(name, device.DefaultMTU)
+		}()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create TUN device for %s: %v\n", name, err)
+			os.Exit(ExitSetupFailed)
+		}
+		if realName, err := tdev.Name(); err == nil {
+			name = realName
+		}
+
+		logger := device.NewLogger(logLevel, name)
+		metrics := device.NewMetrics()
+		dev := device.NewDevice(tdev, device.NewMeteringBind(conn.NewDefaultBind(), metrics), logger)
+		if err := manager.Add(name, dev, metrics); err != nil {
+			logger.Errorf("Failed to register device: %v", err)
+			os.Exit(ExitSetupFailed)
+		}
+
+		if configDir != "" {
+			confPath := filepath.Join(configDir, name+".conf")
+			if _, err := os.Stat(confPath); err == nil {
+				if err := reloadConfig(dev, confPath); err != nil {
+					logger.Errorf("Failed to apply config %s: %v", confPath, err)
+					os.Exit(ExitSetupFailed)
+				}
+			}
+		}
+
+		fileUAPI, ok := activatedFiles[name+".sock"]
+		if !ok {
+			var err error
+			fileUAPI, err = ipc.UAPIOpen(name)
+			if err != nil {
+				logger.Errorf("UAPI listen error: %v", err)
+				os.Exit(ExitSetupFailed)
+			}
+		}
+		uapi, err := ipc.UAPIListen(name, fileUAPI)
+		if err != nil {
+			logger.Errorf("Failed to listen on uapi socket: %v", err)
+			os.Exit(ExitSetupFailed)
+		}
+		listeners = append(listeners, boundUAPI{name: name, dev: dev, ln: uapi})
+
+		logger.Verbosef("Device started")
+	}
+
+	// Each interface keeps its own UAPI socket (ipc already disambiguates
+	// them by path), so its accept loop can hand connections straight to
+	// the Device it was opened for.
+	for _, b := range listeners {
+		go func(dev *device.Device, ln net.Listener) {
+			for {
+				c, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				go dev.IpcHandle(c)
+			}
+		}(b.dev, b.ln)
+	}
+
+	if addr := os.Getenv("WG_METRICS_LISTEN"); addr != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			manager.WritePrometheus(w)
+		})
+		metricsSrv := &http.Server{Addr: addr, Handler: mux}
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "Metrics server failed: %v\n", err)
+			}
+		}()
+		defer metricsSrv.Close()
+	}
+
+	_ = sdnotify.Ready()
+
+	if interval, ok := sdnotify.WatchdogInterval(); ok {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				_ = sdnotify.Watchdog()
+			}
+		}()
+	}
+
+	if configDir != "" {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, unix.SIGHUP)
+		go func() {
+			for range hup {
+				_ = sdnotify.Reloading()
+				for _, name := range manager.Names() {
+					dev, ok := manager.Get(name)
+					if !ok {
+						continue
+					}
+					confPath := filepath.Join(configDir, name+".conf")
+					if err := reloadConfig(dev, confPath); err != nil {
+						fmt.Fprintf(os.Stderr, "Failed to reload %s: %v\n", name, err)
+					}
+				}
+				_ = sdnotify.Ready()
+			}
+		}()
+	}
+
+	term := make(chan os.Signal, 1)
+	signal.Notify(term, unix.SIGTERM)
+	signal.Notify(term, os.Interrupt)
+
+	select {
+	case <-term:
+	case <-manager.Wait():
+	}
+
+	_ = sdnotify.Stopping()
+	for _, b := range listeners {
+		b.ln.Close()
+	}
+	manager.CloseAll()
+}